@@ -0,0 +1,71 @@
+package main
+
+import "time"
+
+// Config holds every setting the action's backends read. loadConfig builds one from this action's
+// inputs; tests construct one directly.
+type Config struct {
+	// RegistryType selects the Publisher backend newPublisher constructs: "tfc" (default),
+	// "gitlab", or "oci".
+	RegistryType string
+
+	// TFAddress, TFToken, TFOrganization, and TFRegistryName configure the Terraform Cloud (or
+	// compatible) registry backend.
+	TFAddress      string
+	TFToken        string
+	TFOrganization string
+	TFRegistryName string
+
+	// TokenExchangeURL, ImpersonateOrg, ImpersonateTeam, and ImpersonateUsername configure
+	// ExchangeTokenSource; ImpersonateUsername being set is what enables impersonation in
+	// newTFClientMiddleware.
+	TokenExchangeURL    string
+	ImpersonateOrg      string
+	ImpersonateTeam     string
+	ImpersonateUsername string
+
+	// UploadChunkSizeBytes, UploadMaxRetryAttempts, UploadRetryBaseDelay, and UploadRetryMaxDelay
+	// tune tfClientMiddleware's chunked upload and retry/backoff behavior; zero values fall back to
+	// the package defaults.
+	UploadChunkSizeBytes   int64
+	UploadMaxRetryAttempts int
+	UploadRetryBaseDelay   time.Duration
+	UploadRetryMaxDelay    time.Duration
+
+	// GitLabAddress, GitLabProjectID, and GitLabToken configure the GitLab Terraform Module
+	// Registry backend.
+	GitLabAddress   string
+	GitLabProjectID string
+	GitLabToken     string
+
+	// OCIRegistry, OCIRepository, OCIUsername, and OCIPassword configure the OCI Distribution Spec
+	// registry backend.
+	OCIRegistry   string
+	OCIRepository string
+	OCIUsername   string
+	OCIPassword   string
+
+	// Namespace, ProviderName, Version, and Protocols identify the provider version being
+	// published.
+	Namespace    string
+	ProviderName string
+	Version      string
+	Protocols    []string
+
+	// ArtifactsDir is scanned for the provider zip archives and generated SHA256SUMS file that
+	// make up the version being published.
+	ArtifactsDir string
+
+	// GPGKeyFilePath, GPGArmoredKeyBase64, and GPGPassphrase locate the key SHA256SUMS is signed
+	// with; see signing.Config.
+	GPGKeyFilePath      string
+	GPGArmoredKeyBase64 string
+	GPGPassphrase       string
+
+	// PublishConcurrency bounds how many platforms publishPlatforms uploads at once; zero or a
+	// negative value falls back to the smaller of runtime.NumCPU() and defaultPublishConcurrency.
+	PublishConcurrency int
+
+	// TracingEnabled wires up OpenTelemetry tracing for every backend's HTTP client.
+	TracingEnabled bool
+}
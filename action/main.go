@@ -0,0 +1,246 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dcarbone/tfcloud-provider-push-action/internal/publisher"
+	"github.com/dcarbone/tfcloud-provider-push-action/internal/signing"
+)
+
+func main() {
+	if err := run(context.Background()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run wires the action's inputs through signing, the selected Publisher backend, and the
+// per-platform publish fan-out, ending with the job summary and step outputs.
+func run(ctx context.Context) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	shutdownTracing, err := initTracing(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(ctx)
+
+	signer, err := signing.NewSigner(signing.Config{
+		KeyFilePath:      cfg.GPGKeyFilePath,
+		ArmoredKeyBase64: cfg.GPGArmoredKeyBase64,
+		Passphrase:       cfg.GPGPassphrase,
+	})
+	if err != nil {
+		return fmt.Errorf("error loading gpg signing key: %w", err)
+	}
+
+	sumsPath, err := signing.GenerateSHA256SUMS(cfg.ArtifactsDir, cfg.ProviderName, cfg.Version)
+	if err != nil {
+		return err
+	}
+
+	sigPath, err := signer.Sign(sumsPath)
+	if err != nil {
+		return err
+	}
+
+	pubKey, err := signer.ArmoredPublicKey()
+	if err != nil {
+		return err
+	}
+
+	platforms, err := discoverPlatforms(cfg.ArtifactsDir, sumsPath)
+	if err != nil {
+		return err
+	}
+
+	pub, err := newPublisher(cfg)
+	if err != nil {
+		return err
+	}
+
+	versionResult, err := pub.CreateVersion(ctx, publisher.VersionRequest{
+		Namespace:    cfg.Namespace,
+		ProviderName: cfg.ProviderName,
+		Version:      cfg.Version,
+		KeyID:        signer.KeyID(),
+		GPGPublicKey: pubKey,
+		Protocols:    cfg.Protocols,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating provider version: %w", err)
+	}
+
+	if err = uploadSumsFiles(ctx, pub, versionResult, sumsPath, sigPath); err != nil {
+		return err
+	}
+
+	outcomes := publishPlatforms(ctx, pub, cfg.Namespace, cfg.ProviderName, cfg.Version, platforms, cfg.PublishConcurrency)
+
+	if err = writeJobSummary(outcomes); err != nil {
+		return err
+	}
+	if err = setPlatformOutputs(outcomes); err != nil {
+		return err
+	}
+
+	for _, o := range outcomes {
+		if !o.Succeeded() {
+			return fmt.Errorf("one or more platforms failed to publish; see the job summary for details")
+		}
+	}
+
+	return nil
+}
+
+// uploadSumsFiles uploads the generated SHA256SUMS and its detached signature to the destinations
+// in versionResult, as returned by CreateVersion.
+func uploadSumsFiles(ctx context.Context, pub publisher.Publisher, versionResult *publisher.VersionResult, sumsPath, sigPath string) error {
+	sums, err := os.ReadFile(sumsPath)
+	if err != nil {
+		return fmt.Errorf("error reading %q: %w", sumsPath, err)
+	}
+	if err = pub.UploadArtifact(ctx, publisher.ArtifactUploadRequest{
+		Destination: versionResult.ShasumsUploadURL,
+		Filename:    filepath.Base(sumsPath),
+		ContentType: binaryOctetStream,
+		File:        sums,
+	}); err != nil {
+		return fmt.Errorf("error uploading %q: %w", filepath.Base(sumsPath), err)
+	}
+
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		return fmt.Errorf("error reading %q: %w", sigPath, err)
+	}
+	if err = pub.UploadArtifact(ctx, publisher.ArtifactUploadRequest{
+		Destination: versionResult.ShasumsSigUploadURL,
+		Filename:    filepath.Base(sigPath),
+		ContentType: binaryOctetStream,
+		File:        sig,
+	}); err != nil {
+		return fmt.Errorf("error uploading %q: %w", filepath.Base(sigPath), err)
+	}
+
+	return nil
+}
+
+// loadConfig builds a Config from this action's inputs, surfaced by the GitHub Actions runner as
+// INPUT_<NAME> environment variables.
+func loadConfig() (*Config, error) {
+	cfg := &Config{
+		RegistryType: getenvDefault("INPUT_REGISTRY-TYPE", registryTypeTFC),
+
+		TFAddress:      getenvDefault("INPUT_TF-ADDRESS", "https://app.terraform.io"),
+		TFToken:        os.Getenv("INPUT_TF-TOKEN"),
+		TFOrganization: os.Getenv("INPUT_TF-ORGANIZATION"),
+		TFRegistryName: os.Getenv("INPUT_TF-REGISTRY-NAME"),
+
+		TokenExchangeURL:    os.Getenv("INPUT_TOKEN-EXCHANGE-URL"),
+		ImpersonateOrg:      os.Getenv("INPUT_IMPERSONATE-ORG"),
+		ImpersonateTeam:     os.Getenv("INPUT_IMPERSONATE-TEAM"),
+		ImpersonateUsername: os.Getenv("INPUT_IMPERSONATE-USERNAME"),
+
+		GitLabAddress:   os.Getenv("INPUT_GITLAB-ADDRESS"),
+		GitLabProjectID: os.Getenv("INPUT_GITLAB-PROJECT-ID"),
+		GitLabToken:     os.Getenv("INPUT_GITLAB-TOKEN"),
+
+		OCIRegistry:   os.Getenv("INPUT_OCI-REGISTRY"),
+		OCIRepository: os.Getenv("INPUT_OCI-REPOSITORY"),
+		OCIUsername:   os.Getenv("INPUT_OCI-USERNAME"),
+		OCIPassword:   os.Getenv("INPUT_OCI-PASSWORD"),
+
+		Namespace:    os.Getenv("INPUT_NAMESPACE"),
+		ProviderName: os.Getenv("INPUT_PROVIDER-NAME"),
+		Version:      os.Getenv("INPUT_VERSION"),
+
+		ArtifactsDir: getenvDefault("INPUT_ARTIFACTS-DIR", "dist"),
+
+		GPGKeyFilePath:      os.Getenv("INPUT_GPG-KEY-FILE"),
+		GPGArmoredKeyBase64: os.Getenv("INPUT_GPG-KEY"),
+		GPGPassphrase:       os.Getenv("INPUT_GPG-PASSPHRASE"),
+
+		TracingEnabled: getenvBool("INPUT_TRACING-ENABLED"),
+	}
+
+	if protocols := os.Getenv("INPUT_PROTOCOLS"); protocols != "" {
+		for _, p := range strings.Split(protocols, ",") {
+			cfg.Protocols = append(cfg.Protocols, strings.TrimSpace(p))
+		}
+	}
+
+	var err error
+	if cfg.UploadChunkSizeBytes, err = getenvInt64("INPUT_UPLOAD-CHUNK-SIZE-BYTES", 0); err != nil {
+		return nil, err
+	}
+
+	maxRetryAttempts, err := getenvInt64("INPUT_UPLOAD-MAX-RETRY-ATTEMPTS", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.UploadMaxRetryAttempts = int(maxRetryAttempts)
+
+	if cfg.UploadRetryBaseDelay, err = getenvDuration("INPUT_UPLOAD-RETRY-BASE-DELAY", 0); err != nil {
+		return nil, err
+	}
+	if cfg.UploadRetryMaxDelay, err = getenvDuration("INPUT_UPLOAD-RETRY-MAX-DELAY", 0); err != nil {
+		return nil, err
+	}
+
+	concurrency, err := getenvInt64("INPUT_PUBLISH-CONCURRENCY", 0)
+	if err != nil {
+		return nil, err
+	}
+	cfg.PublishConcurrency = int(concurrency)
+
+	if cfg.Namespace == "" || cfg.ProviderName == "" || cfg.Version == "" {
+		return nil, fmt.Errorf("the namespace, provider-name, and version inputs are required")
+	}
+
+	return cfg, nil
+}
+
+func getenvDefault(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getenvBool(key string) bool {
+	v, _ := strconv.ParseBool(os.Getenv(key))
+	return v
+}
+
+func getenvInt64(key string, def int64) (int64, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s=%q as an integer: %w", key, v, err)
+	}
+	return n, nil
+}
+
+func getenvDuration(key string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(key)
+	if v == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("error parsing %s=%q as a duration: %w", key, v, err)
+	}
+	return d, nil
+}
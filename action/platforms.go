@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// discoverPlatforms reads sumsPath (the output of signing.GenerateSHA256SUMS) and returns a
+// PlatformSpec for each "<...>_<os>_<arch>.zip" archive it lists, alongside dir, with its data and
+// the SHA256 already computed by GenerateSHA256SUMS rather than rehashed here.
+func discoverPlatforms(dir, sumsPath string) ([]PlatformSpec, error) {
+	data, err := os.ReadFile(sumsPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %q: %w", sumsPath, err)
+	}
+
+	var platforms []PlatformSpec
+	for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("malformed SHA256SUMS line %q", line)
+		}
+		sum, filename := fields[0], fields[1]
+
+		platformOS, arch, err := parsePlatformFilename(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		fileData, err := os.ReadFile(filepath.Join(dir, filename))
+		if err != nil {
+			return nil, fmt.Errorf("error reading provider archive %q: %w", filename, err)
+		}
+
+		platforms = append(platforms, PlatformSpec{
+			OS:       platformOS,
+			Arch:     arch,
+			Filename: filename,
+			Data:     fileData,
+			SHASum:   sum,
+		})
+	}
+
+	return platforms, nil
+}
+
+// parsePlatformFilename extracts the os and arch from a "<name>_<version>_<os>_<arch>.zip"
+// provider archive filename.
+func parsePlatformFilename(filename string) (osName, arch string, err error) {
+	trimmed := strings.TrimSuffix(filename, ".zip")
+	parts := strings.Split(trimmed, "_")
+	if len(parts) < 4 {
+		return "", "", fmt.Errorf("provider archive filename %q does not match <name>_<version>_<os>_<arch>.zip", filename)
+	}
+	return parts[len(parts)-2], parts[len(parts)-1], nil
+}
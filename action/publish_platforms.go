@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/dcarbone/tfcloud-provider-push-action/internal/publisher"
+)
+
+const defaultPublishConcurrency = 4
+
+// PlatformSpec is a single OS/arch build of the provider version being published.
+type PlatformSpec struct {
+	OS       string
+	Arch     string
+	Filename string
+	Data     []byte
+	SHASum   string
+}
+
+// PlatformOutcome is the result of publishing one PlatformSpec.
+type PlatformOutcome struct {
+	OS         string
+	Arch       string
+	PlatformID string
+	SHASum     string
+	Err        error
+}
+
+// Succeeded reports whether this platform published without error.
+func (o PlatformOutcome) Succeeded() bool {
+	return o.Err == nil
+}
+
+// publishPlatforms fans CreatePlatform + UploadArtifact out across platforms using a bounded
+// worker pool, collecting every outcome rather than stopping at the first failure, so a single bad
+// platform doesn't block the others from publishing.
+func publishPlatforms(
+	ctx context.Context,
+	pub publisher.Publisher,
+	namespace, providerName, version string,
+	platforms []PlatformSpec,
+	concurrency int,
+) []PlatformOutcome {
+	if concurrency <= 0 {
+		concurrency = defaultPublishConcurrency
+		if n := runtime.NumCPU(); n < concurrency {
+			concurrency = n
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		sem     = make(chan struct{}, concurrency)
+		results = make([]PlatformOutcome, len(platforms))
+	)
+
+	for i, platform := range platforms {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, platform PlatformSpec) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if r := recover(); r != nil {
+					results[i] = PlatformOutcome{
+						OS:   platform.OS,
+						Arch: platform.Arch,
+						Err:  fmt.Errorf("panic publishing platform %s_%s: %v", platform.OS, platform.Arch, r),
+					}
+				}
+			}()
+			results[i] = publishPlatform(ctx, pub, namespace, providerName, version, platform)
+		}(i, platform)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func publishPlatform(
+	ctx context.Context,
+	pub publisher.Publisher,
+	namespace, providerName, version string,
+	platform PlatformSpec,
+) PlatformOutcome {
+	outcome := PlatformOutcome{OS: platform.OS, Arch: platform.Arch, SHASum: platform.SHASum}
+
+	platformResult, err := pub.CreatePlatform(ctx, publisher.PlatformRequest{
+		Namespace:    namespace,
+		ProviderName: providerName,
+		Version:      version,
+		OS:           platform.OS,
+		Arch:         platform.Arch,
+		Filename:     platform.Filename,
+		SHASum:       platform.SHASum,
+	})
+	if err != nil {
+		outcome.Err = fmt.Errorf("error creating platform %s_%s: %w", platform.OS, platform.Arch, err)
+		return outcome
+	}
+	outcome.PlatformID = platformResult.PlatformID
+
+	if err = pub.UploadArtifact(ctx, publisher.ArtifactUploadRequest{
+		Destination: platformResult.ProviderBinaryUploadURL,
+		Filename:    platform.Filename,
+		ContentType: binaryOctetStream,
+		File:        platform.Data,
+	}); err != nil {
+		outcome.Err = fmt.Errorf("error uploading artifact for platform %s_%s: %w", platform.OS, platform.Arch, err)
+	}
+
+	return outcome
+}
+
+// escapeMarkdownTableCell neutralizes characters that would otherwise split or corrupt a
+// Markdown table cell, such as pipes or newlines embedded in an upstream error message.
+func escapeMarkdownTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return s
+}
+
+// writeJobSummary appends a Markdown table of outcomes to the file named by GITHUB_STEP_SUMMARY.
+// It is a no-op outside of a GitHub Actions job.
+func writeJobSummary(outcomes []PlatformOutcome) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+
+	var b strings.Builder
+	b.WriteString("## Provider publish results\n\n")
+	b.WriteString("| Platform | Status | SHA256 | Platform ID | Error |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, o := range outcomes {
+		status := "✅ succeeded"
+		errMsg := ""
+		if !o.Succeeded() {
+			status = "❌ failed"
+			errMsg = o.Err.Error()
+		}
+		fmt.Fprintf(&b, "| %s_%s | %s | `%s` | `%s` | %s |\n", o.OS, o.Arch, status, o.SHASum, o.PlatformID, escapeMarkdownTableCell(errMsg))
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening GITHUB_STEP_SUMMARY file %q: %w", path, err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(b.String())
+	return err
+}
+
+// setPlatformOutputs writes the succeeded/failed platform lists and per-platform shasums to
+// GITHUB_OUTPUT so downstream steps can react. It is a no-op outside of a GitHub Actions job.
+func setPlatformOutputs(outcomes []PlatformOutcome) error {
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return nil
+	}
+
+	var succeeded, failed, shasums, platformIDs []string
+	for _, o := range outcomes {
+		name := fmt.Sprintf("%s_%s", o.OS, o.Arch)
+		if o.Succeeded() {
+			succeeded = append(succeeded, name)
+		} else {
+			failed = append(failed, name)
+		}
+		shasums = append(shasums, fmt.Sprintf("%s=%s", name, o.SHASum))
+		if o.PlatformID != "" {
+			platformIDs = append(platformIDs, fmt.Sprintf("%s=%s", name, o.PlatformID))
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("error opening GITHUB_OUTPUT file %q: %w", path, err)
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "succeeded_platforms=%s\n", strings.Join(succeeded, ","))
+	fmt.Fprintf(f, "failed_platforms=%s\n", strings.Join(failed, ","))
+	fmt.Fprintf(f, "platform_shasums=%s\n", strings.Join(shasums, ","))
+	fmt.Fprintf(f, "platform_ids=%s\n", strings.Join(platformIDs, ","))
+
+	return nil
+}
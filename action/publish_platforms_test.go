@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dcarbone/tfcloud-provider-push-action/internal/publisher"
+)
+
+// fakePublisher is a minimal publisher.Publisher whose CreatePlatform/UploadArtifact behavior is
+// overridable per test, so publishPlatforms can be exercised without a real registry backend.
+type fakePublisher struct {
+	createPlatform func(req publisher.PlatformRequest) (*publisher.PlatformResult, error)
+	uploadArtifact func(req publisher.ArtifactUploadRequest) error
+}
+
+func (f *fakePublisher) CreateVersion(context.Context, publisher.VersionRequest) (*publisher.VersionResult, error) {
+	return &publisher.VersionResult{}, nil
+}
+
+func (f *fakePublisher) CreatePlatform(_ context.Context, req publisher.PlatformRequest) (*publisher.PlatformResult, error) {
+	if f.createPlatform != nil {
+		return f.createPlatform(req)
+	}
+	return &publisher.PlatformResult{
+		PlatformID:              req.OS + "_" + req.Arch + "-id",
+		ProviderBinaryUploadURL: "https://example.test/" + req.Filename,
+	}, nil
+}
+
+func (f *fakePublisher) UploadArtifact(_ context.Context, req publisher.ArtifactUploadRequest) error {
+	if f.uploadArtifact != nil {
+		return f.uploadArtifact(req)
+	}
+	return nil
+}
+
+func TestPublishPlatforms_AggregatesSuccessAndFailure(t *testing.T) {
+	pub := &fakePublisher{
+		createPlatform: func(req publisher.PlatformRequest) (*publisher.PlatformResult, error) {
+			if req.OS == "freebsd" {
+				return nil, errors.New("create platform failed")
+			}
+			return &publisher.PlatformResult{
+				PlatformID:              req.OS + "_" + req.Arch + "-id",
+				ProviderBinaryUploadURL: "https://example.test/" + req.Filename,
+			}, nil
+		},
+		uploadArtifact: func(req publisher.ArtifactUploadRequest) error {
+			if strings.Contains(req.Filename, "windows") {
+				return errors.New("upload failed")
+			}
+			return nil
+		},
+	}
+
+	platforms := []PlatformSpec{
+		{OS: "linux", Arch: "amd64", Filename: "linux_amd64.zip", SHASum: "aaa"},
+		{OS: "windows", Arch: "amd64", Filename: "windows_amd64.zip", SHASum: "bbb"},
+		{OS: "freebsd", Arch: "amd64", Filename: "freebsd_amd64.zip", SHASum: "ccc"},
+	}
+
+	outcomes := publishPlatforms(context.Background(), pub, "ns", "widget", "1.0.0", platforms, 2)
+	if len(outcomes) != 3 {
+		t.Fatalf("got %d outcomes, want 3", len(outcomes))
+	}
+
+	byOS := make(map[string]PlatformOutcome, len(outcomes))
+	for _, o := range outcomes {
+		byOS[o.OS] = o
+	}
+
+	if !byOS["linux"].Succeeded() {
+		t.Errorf("linux outcome = %+v, want success", byOS["linux"])
+	}
+	if byOS["linux"].PlatformID != "linux_amd64-id" {
+		t.Errorf("linux PlatformID = %q, want %q", byOS["linux"].PlatformID, "linux_amd64-id")
+	}
+
+	if byOS["windows"].Succeeded() {
+		t.Errorf("windows outcome = %+v, want upload failure", byOS["windows"])
+	}
+	if byOS["freebsd"].Succeeded() {
+		t.Errorf("freebsd outcome = %+v, want create-platform failure", byOS["freebsd"])
+	}
+	if byOS["freebsd"].PlatformID != "" {
+		t.Errorf("freebsd PlatformID = %q, want empty (CreatePlatform never returned one)", byOS["freebsd"].PlatformID)
+	}
+}
+
+func TestPublishPlatforms_RecoversPanic(t *testing.T) {
+	pub := &fakePublisher{
+		createPlatform: func(req publisher.PlatformRequest) (*publisher.PlatformResult, error) {
+			if req.OS == "panics" {
+				panic("boom")
+			}
+			return &publisher.PlatformResult{ProviderBinaryUploadURL: "https://example.test/" + req.Filename}, nil
+		},
+	}
+
+	platforms := []PlatformSpec{
+		{OS: "panics", Arch: "amd64", Filename: "panics_amd64.zip"},
+		{OS: "linux", Arch: "amd64", Filename: "linux_amd64.zip"},
+	}
+
+	outcomes := publishPlatforms(context.Background(), pub, "ns", "widget", "1.0.0", platforms, 2)
+
+	var panicked, ok bool
+	for _, o := range outcomes {
+		switch o.OS {
+		case "panics":
+			panicked = !o.Succeeded() && strings.Contains(o.Err.Error(), "panic")
+		case "linux":
+			ok = o.Succeeded()
+		}
+	}
+
+	if !panicked {
+		t.Errorf("expected the panicking platform to surface as a failed outcome mentioning panic, got %+v", outcomes)
+	}
+	if !ok {
+		t.Errorf("expected the non-panicking platform to still succeed, got %+v", outcomes)
+	}
+}
+
+func TestWriteJobSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	outcomes := []PlatformOutcome{
+		{OS: "linux", Arch: "amd64", PlatformID: "plat-1", SHASum: "aaa"},
+		{OS: "windows", Arch: "amd64", Err: errors.New("upload failed: a|b")},
+	}
+
+	if err := writeJobSummary(outcomes); err != nil {
+		t.Fatalf("writeJobSummary() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading summary file: %v", err)
+	}
+	summary := string(data)
+
+	if !strings.Contains(summary, "linux_amd64") || !strings.Contains(summary, "plat-1") {
+		t.Errorf("summary missing succeeded platform row: %s", summary)
+	}
+	if strings.Contains(summary, "upload failed: a|b") {
+		t.Errorf("summary did not escape the pipe in the error message: %s", summary)
+	}
+	if !strings.Contains(summary, "upload failed: a\\|b") {
+		t.Errorf("summary missing escaped error message: %s", summary)
+	}
+}
+
+func TestWriteJobSummary_NoopWithoutEnv(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+
+	if err := writeJobSummary([]PlatformOutcome{{OS: "linux", Arch: "amd64"}}); err != nil {
+		t.Fatalf("writeJobSummary() error = %v, want nil when GITHUB_STEP_SUMMARY is unset", err)
+	}
+}
+
+func TestSetPlatformOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outputs.env")
+	t.Setenv("GITHUB_OUTPUT", path)
+
+	outcomes := []PlatformOutcome{
+		{OS: "linux", Arch: "amd64", PlatformID: "plat-1", SHASum: "aaa"},
+		{OS: "windows", Arch: "amd64", Err: errors.New("failed")},
+	}
+
+	if err := setPlatformOutputs(outcomes); err != nil {
+		t.Fatalf("setPlatformOutputs() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading outputs file: %v", err)
+	}
+	out := string(data)
+
+	wantLines := []string{
+		"succeeded_platforms=linux_amd64",
+		"failed_platforms=windows_amd64",
+		"platform_shasums=linux_amd64=aaa,windows_amd64=",
+		"platform_ids=linux_amd64=plat-1",
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(out, want) {
+			t.Errorf("outputs file missing line %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestEscapeMarkdownTableCell(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain", "upload failed", "upload failed"},
+		{"pipe", "error: a|b", "error: a\\|b"},
+		{"newline", "line one\nline two", "line one line two"},
+		{"pipe and newline", "a|b\nc|d", "a\\|b c\\|d"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := escapeMarkdownTableCell(tc.in); got != tc.want {
+				t.Errorf("escapeMarkdownTableCell(%q) = %q, want %q", tc.in, got, tc.want)
+			}
+		})
+	}
+}
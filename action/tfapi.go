@@ -9,20 +9,28 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/go-cleanhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/dcarbone/tfcloud-provider-push-action/internal/httpretry"
 )
 
 const (
 	headerAccept             = "Accept"
 	headerAuthorization      = "Authorization"
 	headerContentDisposition = "Content-Disposition"
+	headerContentRange       = "Content-Range"
 	headerContentType        = "Content-Type"
+	headerRetryAfter         = "Retry-After"
 
 	applicationVNDAPIJSON = "application/vnd.api+json"
 	applicationJSON       = "application/json"
 	binaryOctetStream     = "binary/octet-stream"
 	attachmentFilenameFmt = "attachment; filename=%q"
+	contentRangeFmt       = "bytes %d-%d/%d"
 
 	pathAPI               = "api"
 	pathOrganizations     = "organizations"
@@ -30,19 +38,70 @@ const (
 	pathRegistryProviders = "registry-providers"
 	pathVersions          = "versions"
 	pathV2                = "v2"
+
+	// defaultChunkSizeBytes is used when Config.UploadChunkSizeBytes is unset.
+	defaultChunkSizeBytes int64 = 8 * 1024 * 1024
+
+	// defaultMaxRetryAttempts is used when Config.UploadMaxRetryAttempts is unset.
+	defaultMaxRetryAttempts = 5
+
+	// defaultRetryBaseDelay and defaultRetryMaxDelay bound the exponential backoff used when
+	// Config.UploadRetryBaseDelay / Config.UploadRetryMaxDelay are unset.
+	defaultRetryBaseDelay = time.Second
+	defaultRetryMaxDelay  = 60 * time.Second
 )
 
 type tfClientMiddleware struct {
 	addr        string
-	bearerToken string
+	tokenSource TokenSource
 	hc          *http.Client
+
+	chunkSizeBytes   int64
+	maxRetryAttempts int
+	retryBaseDelay   time.Duration
+	retryMaxDelay    time.Duration
+
+	tracingEnabled bool
 }
 
 func newTFClientMiddleware(cfg *Config) (*tfClientMiddleware, error) {
 	tm := new(tfClientMiddleware)
 	tm.addr = strings.Trim(cfg.TFAddress, "/")
-	tm.bearerToken = cfg.TFToken
 	tm.hc = cleanhttp.DefaultClient()
+	tm.tracingEnabled = cfg.TracingEnabled
+	if tm.tracingEnabled {
+		tm.hc.Transport = otelhttp.NewTransport(tm.hc.Transport)
+	}
+
+	if cfg.ImpersonateUsername != "" {
+		tm.tokenSource = NewExchangeTokenSource(cfg.TokenExchangeURL, cfg.TFToken, Identity{
+			Org:      cfg.ImpersonateOrg,
+			Team:     cfg.ImpersonateTeam,
+			Username: cfg.ImpersonateUsername,
+		})
+	} else {
+		tm.tokenSource = staticTokenSource(cfg.TFToken)
+	}
+
+	tm.chunkSizeBytes = cfg.UploadChunkSizeBytes
+	if tm.chunkSizeBytes <= 0 {
+		tm.chunkSizeBytes = defaultChunkSizeBytes
+	}
+
+	tm.maxRetryAttempts = cfg.UploadMaxRetryAttempts
+	if tm.maxRetryAttempts <= 0 {
+		tm.maxRetryAttempts = defaultMaxRetryAttempts
+	}
+
+	tm.retryBaseDelay = cfg.UploadRetryBaseDelay
+	if tm.retryBaseDelay <= 0 {
+		tm.retryBaseDelay = defaultRetryBaseDelay
+	}
+
+	tm.retryMaxDelay = cfg.UploadRetryMaxDelay
+	if tm.retryMaxDelay <= 0 {
+		tm.retryMaxDelay = defaultRetryMaxDelay
+	}
 
 	return tm, nil
 }
@@ -53,6 +112,9 @@ func (tm *tfClientMiddleware) copy(hc *http.Client) *tfClientMiddleware {
 	tm2 := new(tfClientMiddleware)
 	*tm2 = *tm
 	tm2.hc = hc
+	if tm2.tracingEnabled {
+		tm2.hc.Transport = otelhttp.NewTransport(tm2.hc.Transport)
+	}
 	return tm2
 }
 
@@ -89,9 +151,76 @@ func (tm *tfClientMiddleware) buildRequest(ctx context.Context, method, routePat
 	return r, err
 }
 
+// do executes the request, retrying retryable failures with exponential backoff and jitter. If the
+// request was built from a re-playable body (buildRequest produces one for []byte and JSON bodies,
+// and for io.Reader bodies only when they are a *bytes.Buffer, *bytes.Reader or *strings.Reader),
+// the body is rewound between attempts via r.GetBody.
 func (tm *tfClientMiddleware) do(r *http.Request) (*http.Response, error) {
-	// todo: this abstraction is here as i plan to eventually move additional logic here.
-	return tm.hc.Do(r)
+	attempts := tm.maxRetryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 && r.GetBody != nil {
+			body, gerr := r.GetBody()
+			if gerr != nil {
+				return nil, fmt.Errorf("error rewinding request body for retry attempt %d: %w", attempt, gerr)
+			}
+			r.Body = body
+		}
+
+		resp, err = tm.hc.Do(r)
+		if err == nil && !httpretry.IsRetryableStatus(resp.StatusCode) && resp.StatusCode != http.StatusUnauthorized {
+			return resp, nil
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		if err == nil && resp.StatusCode == http.StatusUnauthorized {
+			if rerr := tm.refreshBearerToken(r); rerr != nil {
+				return resp, fmt.Errorf("error refreshing token after 401: %w", rerr)
+			}
+		}
+
+		var retryAfter time.Duration
+		if resp != nil {
+			retryAfter = httpretry.ParseRetryAfter(resp.Header.Get(headerRetryAfter))
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-r.Context().Done():
+			return nil, r.Context().Err()
+		case <-time.After(httpretry.BackoffWithJitter(attempt, tm.retryPolicy(), retryAfter)):
+		}
+	}
+
+	return resp, err
+}
+
+// refreshBearerToken forces a fresh token for retrying after a 401. Token sources that don't
+// support invalidation (e.g. staticTokenSource) will simply re-set the same rejected token, and
+// the retry will fail again through the normal non-2xx handling.
+func (tm *tfClientMiddleware) refreshBearerToken(r *http.Request) error {
+	if inv, ok := tm.tokenSource.(invalidatableTokenSource); ok {
+		inv.Invalidate()
+	}
+	return setBearerToken(r.Context(), r, tm.tokenSource)
+}
+
+func (tm *tfClientMiddleware) retryPolicy() httpretry.Policy {
+	return httpretry.Policy{
+		MaxAttempts: tm.maxRetryAttempts,
+		BaseDelay:   tm.retryBaseDelay,
+		MaxDelay:    tm.retryMaxDelay,
+	}
 }
 
 type TFClient struct {
@@ -128,6 +257,15 @@ func (tc *TFProviderClient) CreateProviderVersion(
 	provName string,
 	data TFCreateProviderVersionRequest,
 ) (*TFCreateProviderVersionResponse, error) {
+	ctx, span := startSpan(ctx, "TFProviderClient.CreateProviderVersion",
+		attribute.String(attrTFOrg, orgName),
+		attribute.String(attrTFRegistry, regName),
+		attribute.String(attrTFNamespace, namespace),
+		attribute.String(attrTFProvider, provName),
+		attribute.String(attrTFVersion, data.Version),
+	)
+	defer span.End()
+
 	route := buildRoute(
 		pathAPI,
 		pathV2,
@@ -143,12 +281,18 @@ func (tc *TFProviderClient) CreateProviderVersion(
 	if err != nil {
 		return nil, err
 	}
-	setBearerToken(req, tc.m.bearerToken)
+	if err = setBearerToken(ctx, req, tc.m.tokenSource); err != nil {
+		return nil, err
+	}
 	req.Header.Set(headerContentType, applicationVNDAPIJSON)
 	req.Header.Set(headerAccept, applicationJSON)
 	resp, err := tc.m.do(req)
+	if resp != nil {
+		span.SetAttributes(attribute.Int(attrHTTPStatus, resp.StatusCode))
+	}
 	out := TFCreateProviderVersionResponse{}
 	if err = handleResponse(req, resp, err, &out, http.StatusCreated); err != nil {
+		recordSpanError(span, err)
 		return nil, err
 	}
 	return &out, nil
@@ -163,6 +307,17 @@ func (tc *TFProviderClient) CreateProviderVersionPlatform(
 	provVersion string,
 	data TFCreateProviderVersionPlatformRequest,
 ) (*TFCreateProviderVersionPlatformResponse, error) {
+	ctx, span := startSpan(ctx, "TFProviderClient.CreateProviderVersionPlatform",
+		attribute.String(attrTFOrg, orgName),
+		attribute.String(attrTFRegistry, regName),
+		attribute.String(attrTFNamespace, namespace),
+		attribute.String(attrTFProvider, provName),
+		attribute.String(attrTFVersion, provVersion),
+		attribute.String(attrTFPlatformOS, data.OS),
+		attribute.String(attrTFPlatformArch, data.Arch),
+	)
+	defer span.End()
+
 	route := buildRoute(
 		pathAPI,
 		pathV2,
@@ -180,12 +335,18 @@ func (tc *TFProviderClient) CreateProviderVersionPlatform(
 	if err != nil {
 		return nil, err
 	}
-	setBearerToken(req, tc.m.bearerToken)
+	if err = setBearerToken(ctx, req, tc.m.tokenSource); err != nil {
+		return nil, err
+	}
 	req.Header.Set(headerContentType, applicationVNDAPIJSON)
 	req.Header.Set(headerAccept, applicationJSON)
 	resp, err := tc.m.do(req)
+	if resp != nil {
+		span.SetAttributes(attribute.Int(attrHTTPStatus, resp.StatusCode))
+	}
 	out := TFCreateProviderVersionPlatformResponse{}
 	if err = handleResponse(req, resp, err, &out, http.StatusCreated); err != nil {
+		recordSpanError(span, err)
 		return nil, err
 	}
 	return &out, nil
@@ -200,7 +361,27 @@ func (tc *TFClient) UploadsClient() *TFUploadsClient {
 	return &TFUploadsClient{m: tc.m.copy(cleanhttp.DefaultClient())}
 }
 
+// UploadFile uploads data.File to data.Destination. Files larger than the configured chunk size
+// are uploaded in Content-Range-addressed chunks so that a mid-transfer failure only has to retry
+// the in-flight chunk rather than the whole file; each chunk is itself retried per tfClientMiddleware.do.
 func (tc *TFUploadsClient) UploadFile(ctx context.Context, data TFFileUploadRequest) error {
+	ctx, span := startSpan(ctx, "TFUploadsClient.UploadFile",
+		attribute.String("upload.filename", data.Filename),
+		attribute.Int64(attrUploadBytes, int64(len(data.File))),
+	)
+	defer span.End()
+
+	var err error
+	if int64(len(data.File)) <= tc.m.chunkSizeBytes {
+		err = tc.uploadWhole(ctx, data)
+	} else {
+		err = tc.uploadChunked(ctx, data)
+	}
+	recordSpanError(span, err)
+	return err
+}
+
+func (tc *TFUploadsClient) uploadWhole(ctx context.Context, data TFFileUploadRequest) error {
 	req, err := tc.m.buildRequest(ctx, http.MethodPut, data.Destination, nil, data.File)
 	if err != nil {
 		return err
@@ -210,3 +391,38 @@ func (tc *TFUploadsClient) UploadFile(ctx context.Context, data TFFileUploadRequ
 	resp, err := tc.m.do(req)
 	return handleResponse(req, resp, err, nil, http.StatusOK)
 }
+
+// uploadChunked PUTs data.File in tc.m.chunkSizeBytes pieces, each carrying a Content-Range header
+// identifying its offset. If the destination rejects Content-Range uploads (StatusNotImplemented or
+// StatusBadRequest on the first chunk), it falls back to a single whole-file PUT.
+func (tc *TFUploadsClient) uploadChunked(ctx context.Context, data TFFileUploadRequest) error {
+	total := int64(len(data.File))
+
+	for offset := int64(0); offset < total; {
+		end := offset + tc.m.chunkSizeBytes
+		if end > total {
+			end = total
+		}
+
+		req, err := tc.m.buildRequest(ctx, http.MethodPut, data.Destination, nil, data.File[offset:end])
+		if err != nil {
+			return err
+		}
+		req.Header.Set(headerContentType, data.ContentType)
+		req.Header.Set(headerContentDisposition, fmt.Sprintf(attachmentFilenameFmt, data.Filename))
+		req.Header.Set(headerContentRange, fmt.Sprintf(contentRangeFmt, offset, end-1, total))
+
+		resp, err := tc.m.do(req)
+		if offset == 0 && err == nil && (resp.StatusCode == http.StatusNotImplemented || resp.StatusCode == http.StatusBadRequest) {
+			_ = resp.Body.Close()
+			return tc.uploadWhole(ctx, data)
+		}
+		if err := handleResponse(req, resp, err, nil, http.StatusOK); err != nil {
+			return fmt.Errorf("error uploading chunk at offset %d: %w", offset, err)
+		}
+
+		offset = end
+	}
+
+	return nil
+}
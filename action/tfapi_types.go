@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// buildRoute joins parts into a single "/"-separated route path, relative to tfClientMiddleware.addr.
+func buildRoute(parts ...string) string {
+	return strings.Join(parts, "/")
+}
+
+// handleResponse centralizes the error handling every tfapi method needs: a transport-level error
+// from tfClientMiddleware.do, an unexpected status code (with the response body quoted for
+// context), or a decode failure. If out is non-nil, resp.Body is JSON-decoded into it once the
+// status check passes.
+func handleResponse(req *http.Request, resp *http.Response, err error, out interface{}, wantStatus int) error {
+	if resp != nil {
+		defer resp.Body.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("error executing request %s %s: %w", req.Method, req.URL, err)
+	}
+
+	if resp.StatusCode != wantStatus {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("request %s %s returned status %d: %s", req.Method, req.URL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding response body from %s %s: %w", req.Method, req.URL, err)
+	}
+
+	return nil
+}
+
+// TFCreateProviderVersionRequest describes a new registry provider version. It marshals to the
+// JSON:API document shape the registry-providers endpoint expects.
+type TFCreateProviderVersionRequest struct {
+	Version      string
+	KeyID        string
+	GPGPublicKey string
+	Protocols    []string
+}
+
+func (r TFCreateProviderVersionRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tfJSONAPIDoc{Data: tfJSONAPIReqData{
+		Type: "registry-provider-versions",
+		Attributes: map[string]interface{}{
+			"version":        r.Version,
+			"key-id":         r.KeyID,
+			"gpg-public-key": r.GPGPublicKey,
+			"protocols":      r.Protocols,
+		},
+	}})
+}
+
+// TFCreateProviderVersionResponse is the registry-providers endpoint's JSON:API response, carrying
+// the upload targets for the version's SHA256SUMS and its signature.
+type TFCreateProviderVersionResponse struct {
+	Data struct {
+		ID    string `json:"id"`
+		Type  string `json:"type"`
+		Links struct {
+			ShasumsUpload    string `json:"shasums-upload"`
+			ShasumsSigUpload string `json:"shasums-sig-upload"`
+		} `json:"links"`
+	} `json:"data"`
+}
+
+// TFCreateProviderVersionPlatformRequest describes a single OS/arch build of a provider version.
+// It marshals to the JSON:API document shape the platforms endpoint expects.
+type TFCreateProviderVersionPlatformRequest struct {
+	OS       string
+	Arch     string
+	Filename string
+	Shasum   string
+}
+
+func (r TFCreateProviderVersionPlatformRequest) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tfJSONAPIDoc{Data: tfJSONAPIReqData{
+		Type: "registry-provider-version-platforms",
+		Attributes: map[string]interface{}{
+			"os":       r.OS,
+			"arch":     r.Arch,
+			"filename": r.Filename,
+			"shasum":   r.Shasum,
+		},
+	}})
+}
+
+// TFCreateProviderVersionPlatformResponse is the platforms endpoint's JSON:API response, carrying
+// the platform's ID and the upload target for its provider binary.
+type TFCreateProviderVersionPlatformResponse struct {
+	Data struct {
+		ID    string `json:"id"`
+		Type  string `json:"type"`
+		Links struct {
+			ProviderBinaryUpload string `json:"provider-binary-upload"`
+		} `json:"links"`
+	} `json:"data"`
+}
+
+// TFFileUploadRequest is a single file upload against a pre-signed Destination URL returned by
+// TFProviderClient.
+type TFFileUploadRequest struct {
+	Destination string
+	Filename    string
+	ContentType string
+	File        []byte
+}
+
+// tfJSONAPIDoc is the outermost JSON:API request envelope shared by every tfapi write.
+type tfJSONAPIDoc struct {
+	Data tfJSONAPIReqData `json:"data"`
+}
+
+// tfJSONAPIReqData is the JSON:API "data" object for a write request; Attributes varies per
+// endpoint, so it's a bare map rather than a dedicated struct per request type.
+type tfJSONAPIReqData struct {
+	Type       string                 `json:"type"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dcarbone/tfcloud-provider-push-action/internal/publisher"
+)
+
+// tfcPublisher adapts TFClient to the registry-agnostic publisher.Publisher interface so that the
+// main flow can dispatch through one code path regardless of backend.
+type tfcPublisher struct {
+	orgName string
+	regName string
+
+	prov *TFProviderClient
+	up   *TFUploadsClient
+}
+
+// newTFCPublisher wraps an existing TFClient for orgName/regName as a publisher.Publisher.
+func newTFCPublisher(tc *TFClient, orgName, regName string) publisher.Publisher {
+	return &tfcPublisher{
+		orgName: orgName,
+		regName: regName,
+		prov:    tc.ProviderClient(),
+		up:      tc.UploadsClient(),
+	}
+}
+
+func (p *tfcPublisher) CreateVersion(ctx context.Context, req publisher.VersionRequest) (*publisher.VersionResult, error) {
+	resp, err := p.prov.CreateProviderVersion(ctx, p.orgName, p.regName, req.Namespace, req.ProviderName, TFCreateProviderVersionRequest{
+		Version:      req.Version,
+		KeyID:        req.KeyID,
+		GPGPublicKey: req.GPGPublicKey,
+		Protocols:    req.Protocols,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &publisher.VersionResult{
+		ShasumsUploadURL:    resp.Data.Links.ShasumsUpload,
+		ShasumsSigUploadURL: resp.Data.Links.ShasumsSigUpload,
+	}, nil
+}
+
+func (p *tfcPublisher) CreatePlatform(ctx context.Context, req publisher.PlatformRequest) (*publisher.PlatformResult, error) {
+	resp, err := p.prov.CreateProviderVersionPlatform(ctx, p.orgName, p.regName, req.Namespace, req.ProviderName, req.Version, TFCreateProviderVersionPlatformRequest{
+		OS:       req.OS,
+		Arch:     req.Arch,
+		Filename: req.Filename,
+		Shasum:   req.SHASum,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &publisher.PlatformResult{
+		PlatformID:              resp.Data.ID,
+		ProviderBinaryUploadURL: resp.Data.Links.ProviderBinaryUpload,
+	}, nil
+}
+
+func (p *tfcPublisher) UploadArtifact(ctx context.Context, req publisher.ArtifactUploadRequest) error {
+	return p.up.UploadFile(ctx, TFFileUploadRequest{
+		Destination: req.Destination,
+		Filename:    req.Filename,
+		ContentType: req.ContentType,
+		File:        req.File,
+	})
+}
+
+// newPublisher selects and constructs the Publisher backend named by cfg.RegistryType.
+func newPublisher(cfg *Config) (publisher.Publisher, error) {
+	switch cfg.RegistryType {
+	case "", registryTypeTFC:
+		tc, err := NewTFClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return newTFCPublisher(tc, cfg.TFOrganization, cfg.TFRegistryName), nil
+
+	case registryTypeGitLab:
+		return publisher.NewGitLabPublisher(publisher.GitLabConfig{
+			Addr:           cfg.GitLabAddress,
+			ProjectID:      cfg.GitLabProjectID,
+			Token:          cfg.GitLabToken,
+			TracingEnabled: cfg.TracingEnabled,
+		})
+
+	case registryTypeOCI:
+		return publisher.NewOCIPublisher(publisher.OCIConfig{
+			Registry:       cfg.OCIRegistry,
+			Repository:     cfg.OCIRepository,
+			Username:       cfg.OCIUsername,
+			Password:       cfg.OCIPassword,
+			TracingEnabled: cfg.TracingEnabled,
+		})
+
+	default:
+		return nil, fmt.Errorf("unsupported registry_type %q", cfg.RegistryType)
+	}
+}
+
+// Supported Config.RegistryType values.
+const (
+	registryTypeTFC    = "tfc"
+	registryTypeGitLab = "gitlab"
+	registryTypeOCI    = "oci"
+)
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// TokenSource produces a bearer token for outbound requests. Implementations may cache and refresh
+// the token as needed; Token is called once per request.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// setBearerToken sets the Authorization header on req using a token obtained from ts.
+func setBearerToken(ctx context.Context, req *http.Request, ts TokenSource) error {
+	tok, err := ts.Token(ctx)
+	if err != nil {
+		return fmt.Errorf("error obtaining bearer token: %w", err)
+	}
+	req.Header.Set(headerAuthorization, fmt.Sprintf("Bearer %s", tok))
+	return nil
+}
+
+// staticTokenSource always returns the same token, preserving the previous "bare token string"
+// behavior for deployments that don't use impersonation.
+type staticTokenSource string
+
+func (s staticTokenSource) Token(_ context.Context) (string, error) {
+	return string(s), nil
+}
+
+// invalidatableTokenSource is implemented by token sources that can be told their cached token was
+// rejected, so they re-exchange rather than wait out their TTL.
+type invalidatableTokenSource interface {
+	TokenSource
+	Invalidate()
+}
+
+// Identity is the target principal a service-account token is exchanged on behalf of.
+type Identity struct {
+	Org      string
+	Team     string
+	Username string
+}
+
+func (id Identity) cacheKey() string {
+	return fmt.Sprintf("%s/%s/%s", id.Org, id.Team, id.Username)
+}
+
+// tokenExchangeRequest is the RFC 8693-shaped body sent to the exchange endpoint.
+type tokenExchangeRequest struct {
+	GrantType          string `json:"grant_type"`
+	SubjectToken       string `json:"subject_token"`
+	SubjectTokenType   string `json:"subject_token_type"`
+	RequestedTokenType string `json:"requested_token_type"`
+	Org                string `json:"org"`
+	Team               string `json:"team,omitempty"`
+	Username           string `json:"username"`
+}
+
+// tokenExchangeResponse is the RFC 8693-shaped response from the exchange endpoint.
+type tokenExchangeResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+const (
+	grantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+	tokenTypeAccessToken   = "urn:ietf:params:oauth:token-type:access_token"
+	defaultTokenExpirySkew = 30 * time.Second
+)
+
+type cachedToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// ExchangeTokenSource exchanges a long-lived service-account token for a short-lived, scoped
+// bearer token on behalf of Identity, for orgs where the CI bot user isn't itself allowed to
+// publish. Exchanged tokens are cached per-identity until they're within defaultTokenExpirySkew of
+// expiring, or until Invalidate is called after a 401.
+type ExchangeTokenSource struct {
+	exchangeURL    string
+	serviceAccount string
+	identity       Identity
+	hc             *http.Client
+
+	mu    sync.Mutex
+	cache map[string]cachedToken
+}
+
+// NewExchangeTokenSource returns a TokenSource that exchanges serviceAccountToken for a scoped
+// token for identity via a POST to exchangeURL.
+func NewExchangeTokenSource(exchangeURL, serviceAccountToken string, identity Identity) *ExchangeTokenSource {
+	return &ExchangeTokenSource{
+		exchangeURL:    exchangeURL,
+		serviceAccount: serviceAccountToken,
+		identity:       identity,
+		hc:             cleanhttp.DefaultClient(),
+		cache:          make(map[string]cachedToken),
+	}
+}
+
+func (e *ExchangeTokenSource) Token(ctx context.Context) (string, error) {
+	key := e.identity.cacheKey()
+
+	e.mu.Lock()
+	if c, ok := e.cache[key]; ok && time.Now().Before(c.expiresAt) {
+		e.mu.Unlock()
+		return c.token, nil
+	}
+	e.mu.Unlock()
+
+	tok, expiresIn, err := e.exchange(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	e.mu.Lock()
+	e.cache[key] = cachedToken{
+		token:     tok,
+		expiresAt: time.Now().Add(time.Duration(expiresIn)*time.Second - defaultTokenExpirySkew),
+	}
+	e.mu.Unlock()
+
+	return tok, nil
+}
+
+// Invalidate drops the cached token for this source's identity, forcing the next Token call to
+// re-exchange. Intended to be called after a 401 is observed using a previously-issued token.
+func (e *ExchangeTokenSource) Invalidate() {
+	e.mu.Lock()
+	delete(e.cache, e.identity.cacheKey())
+	e.mu.Unlock()
+}
+
+func (e *ExchangeTokenSource) exchange(ctx context.Context) (string, int64, error) {
+	body, err := json.Marshal(tokenExchangeRequest{
+		GrantType:          grantTypeTokenExchange,
+		SubjectToken:       e.serviceAccount,
+		SubjectTokenType:   tokenTypeAccessToken,
+		RequestedTokenType: tokenTypeAccessToken,
+		Org:                e.identity.Org,
+		Team:               e.identity.Team,
+		Username:           e.identity.Username,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("error marshalling token exchange request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.exchangeURL, bytes.NewBuffer(body))
+	if err != nil {
+		return "", 0, fmt.Errorf("error building token exchange request: %w", err)
+	}
+	req.Header.Set(headerContentType, applicationJSON)
+	req.Header.Set(headerAccept, applicationJSON)
+
+	resp, err := e.hc.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("error calling token exchange endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("token exchange endpoint returned status %d", resp.StatusCode)
+	}
+
+	var out tokenExchangeResponse
+	if err = json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", 0, fmt.Errorf("error decoding token exchange response: %w", err)
+	}
+	if out.AccessToken == "" {
+		return "", 0, fmt.Errorf("token exchange response did not include an access_token")
+	}
+
+	return out.AccessToken, out.ExpiresIn, nil
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStaticTokenSource(t *testing.T) {
+	ts := staticTokenSource("s3cr3t")
+	tok, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if tok != "s3cr3t" {
+		t.Errorf("Token() = %q, want %q", tok, "s3cr3t")
+	}
+}
+
+func newExchangeTestServer(t *testing.T, expiresIn int64) (*httptest.Server, *int32) {
+	t.Helper()
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set(headerContentType, applicationJSON)
+		_ = json.NewEncoder(w).Encode(tokenExchangeResponse{
+			AccessToken: "exchanged-token",
+			ExpiresIn:   expiresIn,
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &calls
+}
+
+func TestExchangeTokenSource_CachesUntilExpiry(t *testing.T) {
+	srv, calls := newExchangeTestServer(t, 3600)
+
+	e := NewExchangeTokenSource(srv.URL, "service-account-token", Identity{Org: "acme", Team: "platform", Username: "ci-bot"})
+
+	for i := 0; i < 3; i++ {
+		tok, err := e.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if tok != "exchanged-token" {
+			t.Errorf("Token() = %q, want %q", tok, "exchanged-token")
+		}
+	}
+
+	if got := atomic.LoadInt32(calls); got != 1 {
+		t.Errorf("exchange endpoint called %d times, want 1 (cached token should be reused)", got)
+	}
+}
+
+func TestExchangeTokenSource_ReExchangesWhenTTLAlreadyElapsed(t *testing.T) {
+	// expires_in of 0 is already inside defaultTokenExpirySkew, so every call should miss the cache.
+	srv, calls := newExchangeTestServer(t, 0)
+
+	e := NewExchangeTokenSource(srv.URL, "service-account-token", Identity{Org: "acme", Username: "ci-bot"})
+
+	if _, err := e.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if _, err := e.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("exchange endpoint called %d times, want 2 (token within skew should not be cached)", got)
+	}
+}
+
+func TestExchangeTokenSource_InvalidateForcesReExchange(t *testing.T) {
+	srv, calls := newExchangeTestServer(t, 3600)
+
+	e := NewExchangeTokenSource(srv.URL, "service-account-token", Identity{Org: "acme", Username: "ci-bot"})
+
+	if _, err := e.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	e.Invalidate()
+	if _, err := e.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("exchange endpoint called %d times, want 2 (Invalidate should force re-exchange)", got)
+	}
+}
+
+func TestExchangeTokenSource_CachesPerIdentity(t *testing.T) {
+	srv, calls := newExchangeTestServer(t, 3600)
+
+	e := NewExchangeTokenSource(srv.URL, "service-account-token", Identity{Org: "acme", Username: "ci-bot"})
+	e.identity = Identity{Org: "acme", Username: "ci-bot"}
+	if _, err := e.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	e.identity = Identity{Org: "acme", Username: "other-user"}
+	if _, err := e.Token(context.Background()); err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+
+	if got := atomic.LoadInt32(calls); got != 2 {
+		t.Errorf("exchange endpoint called %d times, want 2 (distinct identities must not share a cache entry)", got)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies this action's spans in the emitted traces.
+const tracerName = "github.com/dcarbone/tfcloud-provider-push-action"
+
+const defaultOTLPServiceName = "tfcloud-provider-push-action"
+
+// Span attribute keys shared across tfapi methods.
+const (
+	attrTFOrg          = "tf.org"
+	attrTFRegistry     = "tf.registry"
+	attrTFNamespace    = "tf.namespace"
+	attrTFProvider     = "tf.provider"
+	attrTFVersion      = "tf.version"
+	attrTFPlatformOS   = "tf.platform.os"
+	attrTFPlatformArch = "tf.platform.arch"
+	attrHTTPStatus     = "http.status_code"
+	attrUploadBytes    = "upload.bytes"
+)
+
+// initTracing wires up the OpenTelemetry SDK when cfg.TracingEnabled is set, reading the exporter
+// endpoint and service name from the standard OTEL_EXPORTER_OTLP_ENDPOINT / OTEL_SERVICE_NAME env
+// vars. The returned shutdown func flushes and closes the exporter; it is a no-op when tracing is
+// disabled, so callers can always defer it unconditionally.
+func initTracing(ctx context.Context, cfg *Config) (shutdown func(context.Context) error, err error) {
+	noop := func(context.Context) error { return nil }
+
+	if !cfg.TracingEnabled {
+		return noop, nil
+	}
+
+	exp, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return noop, fmt.Errorf("error creating otlp trace exporter: %w", err)
+	}
+
+	serviceName := os.Getenv("OTEL_SERVICE_NAME")
+	if serviceName == "" {
+		serviceName = defaultOTLPServiceName
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return noop, fmt.Errorf("error building otel resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return tp.Shutdown, nil
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startSpan opens a span named name with the given attributes, returning the derived context and
+// the span. Callers are expected to `defer span.End()` and record errors via recordSpanError.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// recordSpanError marks span as failed when err is non-nil; safe to call with a nil err.
+func recordSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
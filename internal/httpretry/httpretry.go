@@ -0,0 +1,127 @@
+// Package httpretry centralizes the retry/backoff logic shared by every HTTP-speaking client in
+// this action (Terraform Cloud, GitLab, and OCI registry backends), so "retry 5xx/429 with
+// exponential backoff and jitter, honoring Retry-After" is implemented once rather than
+// per-backend.
+package httpretry
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy bounds the retry loop.
+type Policy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// DefaultPolicy mirrors the defaults tfClientMiddleware falls back to when Config leaves these
+// values unset.
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 5,
+		BaseDelay:   time.Second,
+		MaxDelay:    60 * time.Second,
+	}
+}
+
+// RetryableStatusCodes are server / intermediary responses worth retrying, per RFC guidance and
+// TFC's own behavior under load.
+var RetryableStatusCodes = map[int]bool{
+	http.StatusRequestTimeout:      true,
+	http.StatusTooEarly:            true,
+	http.StatusTooManyRequests:     true,
+	http.StatusInternalServerError: true,
+	http.StatusBadGateway:          true,
+	http.StatusServiceUnavailable:  true,
+	http.StatusGatewayTimeout:      true,
+}
+
+// IsRetryableStatus reports whether code is worth retrying.
+func IsRetryableStatus(code int) bool {
+	return RetryableStatusCodes[code]
+}
+
+// ParseRetryAfter parses an HTTP Retry-After header value expressed as a number of seconds. The
+// HTTP-date form is not handled since TFC and fronting proxies emit the delay-seconds form.
+func ParseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	secs, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// BackoffWithJitter computes the delay before the next retry attempt (0-indexed). When the server
+// provided a Retry-After duration it takes precedence over the computed backoff. Otherwise the
+// delay is at least half of BaseDelay<<attempt (itself capped at MaxDelay) and never exceeds
+// MaxDelay.
+func BackoffWithJitter(attempt int, policy Policy, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	d := policy.BaseDelay << attempt
+	if d <= 0 || d > policy.MaxDelay {
+		d = policy.MaxDelay
+	}
+
+	delay := time.Duration(rand.Int63n(int64(d))) + d/2
+	if delay > policy.MaxDelay {
+		delay = policy.MaxDelay
+	}
+	return delay
+}
+
+// Do executes newRequest and sends it via hc, retrying retryable status codes and network errors
+// per policy. newRequest is called once per attempt so the caller can supply a fresh body reader
+// each time, rather than requiring a rewindable http.Request.
+func Do(ctx context.Context, hc *http.Client, policy Policy, newRequest func() (*http.Request, error)) (*http.Response, error) {
+	attempts := policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		var req *http.Request
+		if req, err = newRequest(); err != nil {
+			return nil, fmt.Errorf("error building request for retry attempt %d: %w", attempt, err)
+		}
+
+		resp, err = hc.Do(req)
+		if err == nil && !IsRetryableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		var retryAfter time.Duration
+		if resp != nil {
+			retryAfter = ParseRetryAfter(resp.Header.Get("Retry-After"))
+			_ = resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(BackoffWithJitter(attempt, policy, retryAfter)):
+		}
+	}
+
+	return resp, err
+}
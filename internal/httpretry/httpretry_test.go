@@ -0,0 +1,72 @@
+package httpretry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		want time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "5", 5 * time.Second},
+		{"whitespace", " 12 ", 12 * time.Second},
+		{"negative", "-1", 0},
+		{"non-numeric", "Wed, 21 Oct 2015 07:28:00 GMT", 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := ParseRetryAfter(tc.in); got != tc.want {
+				t.Errorf("ParseRetryAfter(%q) = %v, want %v", tc.in, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestBackoffWithJitter_RetryAfterTakesPrecedence(t *testing.T) {
+	policy := DefaultPolicy()
+	got := BackoffWithJitter(3, policy, 7*time.Second)
+	if got != 7*time.Second {
+		t.Errorf("BackoffWithJitter with retryAfter set = %v, want 7s", got)
+	}
+}
+
+func TestBackoffWithJitter_BoundedByMaxDelay(t *testing.T) {
+	policy := Policy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+
+	// A high attempt count would overflow base<<attempt well past MaxDelay; the jittered result
+	// must still never exceed MaxDelay.
+	for attempt := 0; attempt < 10; attempt++ {
+		d := BackoffWithJitter(attempt, policy, 0)
+		if d <= 0 {
+			t.Fatalf("attempt %d: BackoffWithJitter returned non-positive delay %v", attempt, d)
+		}
+		if d > policy.MaxDelay {
+			t.Fatalf("attempt %d: BackoffWithJitter = %v, want <= %v", attempt, d, policy.MaxDelay)
+		}
+	}
+}
+
+func TestBackoffWithJitter_GrowsWithAttempt(t *testing.T) {
+	policy := Policy{BaseDelay: time.Millisecond, MaxDelay: time.Hour}
+
+	// d/2 is the floor of each attempt's delay; confirm it strictly increases until capped.
+	floor := func(attempt int) time.Duration {
+		d := policy.BaseDelay << attempt
+		return d / 2
+	}
+
+	if floor(2) <= floor(1) || floor(1) <= floor(0) {
+		t.Fatalf("expected strictly increasing backoff floors, got attempt0=%v attempt1=%v attempt2=%v", floor(0), floor(1), floor(2))
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		if got := BackoffWithJitter(attempt, policy, 0); got < floor(attempt) {
+			t.Fatalf("attempt %d: BackoffWithJitter = %v, want >= %v", attempt, got, floor(attempt))
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/dcarbone/tfcloud-provider-push-action/internal/httpretry"
+)
+
+// GitLabConfig configures a GitLabPublisher.
+type GitLabConfig struct {
+	// Addr is the GitLab instance base address, e.g. "https://gitlab.com".
+	Addr string
+	// ProjectID is the numeric or URL-encoded-path project ID that owns the registry.
+	ProjectID string
+	// Token is a PRIVATE-TOKEN or CI_JOB_TOKEN with permission to publish to the project's
+	// Terraform module registry.
+	Token string
+	// TracingEnabled wraps the HTTP transport with an OpenTelemetry span per request, mirroring
+	// tfClientMiddleware's behavior for the TFC backend.
+	TracingEnabled bool
+}
+
+// GitLabPublisher publishes provider versions to GitLab's Terraform Module Registry API
+// (/api/v4/projects/:id/packages/terraform/modules/...). It satisfies Publisher.
+type GitLabPublisher struct {
+	cfg GitLabConfig
+	hc  *http.Client
+}
+
+// NewGitLabPublisher returns a Publisher backed by a GitLab project's Terraform registry.
+func NewGitLabPublisher(cfg GitLabConfig) (*GitLabPublisher, error) {
+	if cfg.ProjectID == "" {
+		return nil, fmt.Errorf("gitlab publisher: ProjectID is required")
+	}
+
+	hc := cleanhttp.DefaultClient()
+	if cfg.TracingEnabled {
+		hc.Transport = otelhttp.NewTransport(hc.Transport)
+	}
+
+	return &GitLabPublisher{
+		cfg: cfg,
+		hc:  hc,
+	}, nil
+}
+
+func (p *GitLabPublisher) baseURL() string {
+	return fmt.Sprintf("%s/api/v4/projects/%s/packages/terraform/modules", strings.TrimRight(p.cfg.Addr, "/"), p.cfg.ProjectID)
+}
+
+// CreateVersion makes no request: it returns the <namespace>/<name>/<version> package path under
+// baseURL. GitLab has no separate version-level shasums upload step, so ShasumsUploadURL and
+// ShasumsSigUploadURL both point at the same per-version package path; UploadArtifact
+// distinguishes them by filename.
+func (p *GitLabPublisher) CreateVersion(ctx context.Context, req VersionRequest) (*VersionResult, error) {
+	dest := fmt.Sprintf("%s/%s/%s/%s", p.baseURL(), req.Namespace, req.ProviderName, req.Version)
+	return &VersionResult{
+		ShasumsUploadURL:    dest,
+		ShasumsSigUploadURL: dest,
+	}, nil
+}
+
+// CreatePlatform is a no-op for GitLab: the module registry addresses a version as a single
+// package rather than per-platform sub-resources, so the upload destination is the same as
+// CreateVersion's.
+func (p *GitLabPublisher) CreatePlatform(ctx context.Context, req PlatformRequest) (*PlatformResult, error) {
+	dest := fmt.Sprintf("%s/%s/%s/%s", p.baseURL(), req.Namespace, req.ProviderName, req.Version)
+	return &PlatformResult{ProviderBinaryUploadURL: dest}, nil
+}
+
+// UploadArtifact PUTs req.File to req.Destination/req.Filename, GitLab's generic package file
+// upload convention, retrying retryable failures (5xx/429, honoring Retry-After) per
+// httpretry.DefaultPolicy so a flaky upload of a large provider binary doesn't fail the whole run.
+func (p *GitLabPublisher) UploadArtifact(ctx context.Context, req ArtifactUploadRequest) error {
+	dest := fmt.Sprintf("%s/%s", strings.TrimRight(req.Destination, "/"), req.Filename)
+
+	resp, err := httpretry.Do(ctx, p.hc, httpretry.DefaultPolicy(), func() (*http.Request, error) {
+		r, err := http.NewRequestWithContext(ctx, http.MethodPut, dest, bytes.NewReader(req.File))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", req.ContentType)
+		r.Header.Set("PRIVATE-TOKEN", p.cfg.Token)
+		return r, nil
+	})
+	if err != nil {
+		return fmt.Errorf("error uploading %q to gitlab: %w", req.Filename, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		var body bytes.Buffer
+		_, _ = body.ReadFrom(resp.Body)
+		return fmt.Errorf("gitlab upload of %q failed with status %d: %s", req.Filename, resp.StatusCode, body.String())
+	}
+
+	return nil
+}
@@ -0,0 +1,341 @@
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+
+	"github.com/dcarbone/tfcloud-provider-push-action/internal/httpretry"
+)
+
+// ociProviderArtifactType is the media type used for provider zip layers, per the emerging
+// OCI-Terraform-provider packaging convention.
+const ociProviderArtifactType = "application/vnd.terraform.provider.v1.archive+zip"
+
+// ociManifestType is the media type used for the manifest wrapping each platform's layers.
+const ociManifestType = "application/vnd.oci.image.manifest.v1+json"
+
+// ociEmptyConfigType is the media type of the minimal config blob every OCI manifest must
+// reference; this publisher doesn't use the config blob for anything beyond satisfying the spec.
+const ociEmptyConfigType = "application/vnd.oci.empty.v1+json"
+
+// ociShasumsArtifactType and ociShasumsSigArtifactType are the media types for the version-level
+// checksum manifest and its detached signature, included as extra layers in each platform's
+// manifest so a pull of any one platform carries its own verification material.
+const (
+	ociShasumsArtifactType    = "application/vnd.terraform.provider.v1.shasums"
+	ociShasumsSigArtifactType = "application/vnd.terraform.provider.v1.shasums.sig"
+)
+
+// ociDescriptor is an OCI content descriptor, per the OCI Image Manifest Spec.
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// ociManifest is a minimal OCI Image Manifest: one config blob plus a set of layer blobs.
+type ociManifest struct {
+	SchemaVersion int             `json:"schemaVersion"`
+	MediaType     string          `json:"mediaType"`
+	Config        ociDescriptor   `json:"config"`
+	Layers        []ociDescriptor `json:"layers"`
+}
+
+// OCIConfig configures an OCIPublisher against any registry speaking the OCI Distribution Spec
+// (Harbor, JFrog Artifactory, GHCR, ...).
+type OCIConfig struct {
+	// Registry is the registry host, e.g. "registry.example.com".
+	Registry string
+	// Repository is the repository path providers are pushed under, e.g.
+	// "terraform-providers/<namespace>/<name>".
+	Repository string
+	// Username and Password are sent as HTTP basic auth on every registry request. Leave both
+	// empty for anonymous push (rarely supported for writes).
+	Username string
+	Password string
+	// TracingEnabled wraps the HTTP transport with an OpenTelemetry span per request, mirroring
+	// tfClientMiddleware's behavior for the TFC backend.
+	TracingEnabled bool
+}
+
+// OCIPublisher packages a provider version as an OCI artifact and pushes it to an
+// OCI-Distribution-Spec registry. It satisfies Publisher.
+//
+// Each platform is pushed as its own tagged manifest ("<version>_<os>_<arch>") whose layers are
+// that platform's provider zip plus the version's shared SHA256SUMS and SHA256SUMS.sig blobs, so
+// a pull of any single platform tag carries its own verification material. Callers must upload the
+// version's SHA256SUMS and SHA256SUMS.sig (CreateVersion's destinations) before any platform's zip,
+// or UploadArtifact will fail rather than push a manifest missing those layers.
+type OCIPublisher struct {
+	cfg OCIConfig
+	hc  *http.Client
+
+	mu            sync.Mutex
+	emptyConfig   *ociDescriptor
+	shasums       *ociDescriptor
+	shasumsSig    *ociDescriptor
+	platformBlobs map[string]ociDescriptor // keyed by "<os>_<arch>"
+}
+
+// NewOCIPublisher returns a Publisher backed by an OCI Distribution Spec registry.
+func NewOCIPublisher(cfg OCIConfig) (*OCIPublisher, error) {
+	if cfg.Registry == "" || cfg.Repository == "" {
+		return nil, fmt.Errorf("oci publisher: Registry and Repository are required")
+	}
+	hc := cleanhttp.DefaultClient()
+	if cfg.TracingEnabled {
+		hc.Transport = otelhttp.NewTransport(hc.Transport)
+	}
+
+	return &OCIPublisher{
+		cfg:           cfg,
+		hc:            hc,
+		platformBlobs: make(map[string]ociDescriptor),
+	}, nil
+}
+
+func (p *OCIPublisher) blobsURL() string {
+	return fmt.Sprintf("https://%s/v2/%s/blobs/uploads/", strings.TrimRight(p.cfg.Registry, "/"), p.cfg.Repository)
+}
+
+func (p *OCIPublisher) manifestURL(tag string) string {
+	return fmt.Sprintf("https://%s/v2/%s/manifests/%s", strings.TrimRight(p.cfg.Registry, "/"), p.cfg.Repository, tag)
+}
+
+// destinationFor returns a blob upload URL carrying artifactKind in its fragment. The fragment is
+// never sent over the wire (net/http strips it from the request line) but lets UploadArtifact
+// recover which blob it just pushed once the registry hands back a real upload Location.
+func (p *OCIPublisher) destinationFor(artifactKind string) string {
+	return p.blobsURL() + "#" + url.QueryEscape(artifactKind)
+}
+
+// CreateVersion returns the blob upload endpoints for the version's SHA256SUMS and
+// SHA256SUMS.sig; there is no separate version-creation call in the OCI Distribution Spec; a
+// version only becomes addressable once CreatePlatform's manifest tags are pushed.
+func (p *OCIPublisher) CreateVersion(ctx context.Context, req VersionRequest) (*VersionResult, error) {
+	return &VersionResult{
+		ShasumsUploadURL:    p.destinationFor("shasums"),
+		ShasumsSigUploadURL: p.destinationFor("shasums.sig"),
+	}, nil
+}
+
+// CreatePlatform returns the blob upload endpoint for a platform's provider zip. UploadArtifact
+// pushes the blob then finalizes and pushes the "<version>_<os>_<arch>"-tagged manifest
+// referencing it, once it is called for this platform.
+func (p *OCIPublisher) CreatePlatform(ctx context.Context, req PlatformRequest) (*PlatformResult, error) {
+	return &PlatformResult{
+		ProviderBinaryUploadURL: p.destinationFor(fmt.Sprintf("platform:%s:%s:%s", req.Version, req.OS, req.Arch)),
+	}, nil
+}
+
+// UploadArtifact performs a monolithic blob upload (POST to start, PUT with the digest to
+// complete) against req.Destination, retrying retryable failures per httpretry.DefaultPolicy. Once
+// a platform zip blob lands, it immediately pushes that platform's manifest.
+func (p *OCIPublisher) UploadArtifact(ctx context.Context, req ArtifactUploadRequest) error {
+	destURL, err := url.Parse(req.Destination)
+	if err != nil {
+		return fmt.Errorf("error parsing oci upload destination for %q: %w", req.Filename, err)
+	}
+	artifactKind, err := url.QueryUnescape(destURL.Fragment)
+	if err != nil {
+		return fmt.Errorf("error decoding oci artifact kind for %q: %w", req.Filename, err)
+	}
+	destURL.Fragment = ""
+
+	desc, err := p.pushBlob(ctx, destURL.String(), req)
+	if err != nil {
+		return err
+	}
+
+	var platformVersion, platformOS, platformArch string
+	isPlatform := strings.HasPrefix(artifactKind, "platform:")
+	if isPlatform {
+		parts := strings.SplitN(strings.TrimPrefix(artifactKind, "platform:"), ":", 3)
+		if len(parts) != 3 {
+			return fmt.Errorf("oci publisher: malformed platform artifact kind %q", artifactKind)
+		}
+		platformVersion, platformOS, platformArch = parts[0], parts[1], parts[2]
+	}
+
+	p.mu.Lock()
+	switch {
+	case artifactKind == "shasums":
+		p.shasums = &desc
+	case artifactKind == "shasums.sig":
+		p.shasumsSig = &desc
+	case isPlatform:
+		p.platformBlobs[platformOS+"_"+platformArch] = desc
+	}
+	p.mu.Unlock()
+
+	if isPlatform {
+		return p.pushPlatformManifest(ctx, platformVersion, platformOS, platformArch)
+	}
+
+	return nil
+}
+
+// pushBlob uploads req.File's bytes as a single blob, retrying the initiate request and the
+// complete request each on its own on retryable failures.
+func (p *OCIPublisher) pushBlob(ctx context.Context, startURL string, req ArtifactUploadRequest) (ociDescriptor, error) {
+	startResp, err := httpretry.Do(ctx, p.hc, httpretry.DefaultPolicy(), func() (*http.Request, error) {
+		r, err := http.NewRequestWithContext(ctx, http.MethodPost, startURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		p.setAuth(r)
+		return r, nil
+	})
+	if err != nil {
+		return ociDescriptor{}, fmt.Errorf("error starting oci blob upload for %q: %w", req.Filename, err)
+	}
+	defer startResp.Body.Close()
+
+	if startResp.StatusCode != http.StatusAccepted {
+		return ociDescriptor{}, fmt.Errorf("oci blob upload init for %q failed with status %d", req.Filename, startResp.StatusCode)
+	}
+
+	uploadURL := startResp.Header.Get("Location")
+	if uploadURL == "" {
+		return ociDescriptor{}, fmt.Errorf("oci registry did not return an upload Location for %q", req.Filename)
+	}
+
+	digest := sha256Digest(req.File)
+	sep := "?"
+	if strings.Contains(uploadURL, "?") {
+		sep = "&"
+	}
+	putURL := fmt.Sprintf("%s%sdigest=%s", uploadURL, sep, digest)
+
+	putResp, err := httpretry.Do(ctx, p.hc, httpretry.DefaultPolicy(), func() (*http.Request, error) {
+		r, err := http.NewRequestWithContext(ctx, http.MethodPut, putURL, bytes.NewReader(req.File))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", "application/octet-stream")
+		p.setAuth(r)
+		return r, nil
+	})
+	if err != nil {
+		return ociDescriptor{}, fmt.Errorf("error completing oci blob upload for %q: %w", req.Filename, err)
+	}
+	defer putResp.Body.Close()
+
+	if putResp.StatusCode != http.StatusCreated {
+		return ociDescriptor{}, fmt.Errorf("oci blob upload of %q failed with status %d", req.Filename, putResp.StatusCode)
+	}
+
+	return ociDescriptor{MediaType: ociProviderArtifactType, Digest: digest, Size: int64(len(req.File))}, nil
+}
+
+// ensureEmptyConfig pushes the empty "{}" config blob every OCI manifest must reference, the
+// first time it's needed, and caches its descriptor for subsequent manifests.
+func (p *OCIPublisher) ensureEmptyConfig(ctx context.Context) (ociDescriptor, error) {
+	p.mu.Lock()
+	if p.emptyConfig != nil {
+		desc := *p.emptyConfig
+		p.mu.Unlock()
+		return desc, nil
+	}
+	p.mu.Unlock()
+
+	const emptyConfigBody = "{}"
+	desc, err := p.pushBlob(ctx, p.blobsURL(), ArtifactUploadRequest{
+		Filename: "config.json",
+		File:     []byte(emptyConfigBody),
+	})
+	if err != nil {
+		return ociDescriptor{}, fmt.Errorf("error pushing empty oci config blob: %w", err)
+	}
+	desc.MediaType = ociEmptyConfigType
+
+	p.mu.Lock()
+	p.emptyConfig = &desc
+	p.mu.Unlock()
+
+	return desc, nil
+}
+
+// pushPlatformManifest builds and pushes the "<version>_<os>_<arch>"-tagged manifest for a
+// platform whose zip blob has just landed, including the version's SHA256SUMS/.sig blobs as
+// additional layers. Callers are expected to have uploaded the version-level SHA256SUMS and
+// SHA256SUMS.sig before any platform's zip; pushPlatformManifest errors rather than publishing a
+// manifest that's silently missing its checksum/signature layers.
+func (p *OCIPublisher) pushPlatformManifest(ctx context.Context, version, os, arch string) error {
+	configDesc, err := p.ensureEmptyConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	zipDesc, ok := p.platformBlobs[os+"_"+arch]
+	shasums, shasumsSig := p.shasums, p.shasumsSig
+	p.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("oci publisher: no blob recorded for platform %s_%s", os, arch)
+	}
+	if shasums == nil || shasumsSig == nil {
+		return fmt.Errorf("oci publisher: cannot push manifest for platform %s_%s before the version's SHA256SUMS and SHA256SUMS.sig have been uploaded", os, arch)
+	}
+
+	layers := []ociDescriptor{
+		zipDesc,
+		{MediaType: ociShasumsArtifactType, Digest: shasums.Digest, Size: shasums.Size},
+		{MediaType: ociShasumsSigArtifactType, Digest: shasumsSig.Digest, Size: shasumsSig.Size},
+	}
+
+	manifest := ociManifest{
+		SchemaVersion: 2,
+		MediaType:     ociManifestType,
+		Config:        configDesc,
+		Layers:        layers,
+	}
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("error marshalling oci manifest for %s_%s: %w", os, arch, err)
+	}
+
+	tag := fmt.Sprintf("%s_%s_%s", version, os, arch)
+	resp, err := httpretry.Do(ctx, p.hc, httpretry.DefaultPolicy(), func() (*http.Request, error) {
+		r, err := http.NewRequestWithContext(ctx, http.MethodPut, p.manifestURL(tag), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Content-Type", ociManifestType)
+		p.setAuth(r)
+		return r, nil
+	})
+	if err != nil {
+		return fmt.Errorf("error pushing oci manifest for %s_%s: %w", os, arch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("oci manifest push for %s_%s failed with status %d", os, arch, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (p *OCIPublisher) setAuth(r *http.Request) {
+	if p.cfg.Username != "" || p.cfg.Password != "" {
+		r.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+	}
+}
+
+func sha256Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("sha256:%x", sum)
+}
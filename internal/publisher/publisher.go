@@ -0,0 +1,67 @@
+// Package publisher defines a registry-agnostic interface for publishing a built Terraform
+// provider, so that the action can target Terraform Cloud, GitLab's Terraform Registry, or an
+// OCI-based registry (Harbor, JFrog, GHCR) through the same call sites.
+package publisher
+
+import "context"
+
+// Publisher publishes a single provider version, its per-platform metadata, and the associated
+// artifacts to a registry backend.
+type Publisher interface {
+	// CreateVersion registers a new provider version and returns the shasums/signature upload
+	// targets the caller must populate before platforms can be created.
+	CreateVersion(ctx context.Context, req VersionRequest) (*VersionResult, error)
+
+	// CreatePlatform registers a single OS/arch combination for a previously created version and
+	// returns the provider zip upload target for that platform.
+	CreatePlatform(ctx context.Context, req PlatformRequest) (*PlatformResult, error)
+
+	// UploadArtifact uploads a single file (provider zip, SHA256SUMS, or SHA256SUMS.sig) to the
+	// destination returned by CreateVersion or CreatePlatform.
+	UploadArtifact(ctx context.Context, req ArtifactUploadRequest) error
+}
+
+// VersionRequest describes the provider version being published.
+type VersionRequest struct {
+	Namespace    string
+	ProviderName string
+	Version      string
+	KeyID        string
+	GPGPublicKey string
+	Protocols    []string
+}
+
+// VersionResult carries the upload targets for the version-level SHA256SUMS and its signature.
+type VersionResult struct {
+	ShasumsUploadURL    string
+	ShasumsSigUploadURL string
+}
+
+// PlatformRequest describes a single OS/arch build of a provider version.
+type PlatformRequest struct {
+	Namespace    string
+	ProviderName string
+	Version      string
+	OS           string
+	Arch         string
+	Filename     string
+	SHASum       string
+}
+
+// PlatformResult carries the upload target for a platform's provider zip artifact.
+type PlatformResult struct {
+	// PlatformID is the backend's identifier for the created platform resource (e.g. TFC's
+	// registry-provider-platforms id). Backends without a distinct platform resource (GitLab, OCI)
+	// may leave this empty.
+	PlatformID              string
+	ProviderBinaryUploadURL string
+}
+
+// ArtifactUploadRequest is a single file upload against a destination returned by CreateVersion or
+// CreatePlatform.
+type ArtifactUploadRequest struct {
+	Destination string
+	Filename    string
+	ContentType string
+	File        []byte
+}
@@ -0,0 +1,88 @@
+package signing
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+)
+
+const sha256SumsFmt = "terraform-provider-%s_%s_SHA256SUMS"
+
+// GenerateSHA256SUMS hashes every *.zip archive in dir and writes
+// terraform-provider-<name>_<version>_SHA256SUMS alongside them, in the "<hex>  <filename>" format
+// the registry provider version endpoint expects. It returns the path to the written file.
+func GenerateSHA256SUMS(dir, providerName, version string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("error reading provider archive directory %q: %w", dir, err)
+	}
+
+	var filenames []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".zip") {
+			continue
+		}
+		filenames = append(filenames, entry.Name())
+	}
+	sort.Strings(filenames)
+
+	lines := make([]string, 0, len(filenames))
+	for _, name := range filenames {
+		sum, err := sha256File(filepath.Join(dir, name))
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf("%x  %s", sum, name))
+	}
+
+	sumsPath := filepath.Join(dir, fmt.Sprintf(sha256SumsFmt, providerName, version))
+	if err = os.WriteFile(sumsPath, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		return "", fmt.Errorf("error writing %q: %w", sumsPath, err)
+	}
+
+	return sumsPath, nil
+}
+
+func sha256File(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %q: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, fmt.Errorf("error hashing %q: %w", path, err)
+	}
+
+	return h.Sum(nil), nil
+}
+
+// Sign produces an ASCII-armored detached signature of sumsPath (e.g. the SHA256SUMS file from
+// GenerateSHA256SUMS), writing it to sumsPath+".sig" and returning its path.
+func (s *Signer) Sign(sumsPath string) (string, error) {
+	in, err := os.Open(sumsPath)
+	if err != nil {
+		return "", fmt.Errorf("error opening %q for signing: %w", sumsPath, err)
+	}
+	defer in.Close()
+
+	sigPath := sumsPath + ".sig"
+	out, err := os.Create(sigPath)
+	if err != nil {
+		return "", fmt.Errorf("error creating %q: %w", sigPath, err)
+	}
+	defer out.Close()
+
+	if err = openpgp.ArmoredDetachSign(out, s.entity, in, nil); err != nil {
+		return "", fmt.Errorf("error signing %q: %w", sumsPath, err)
+	}
+
+	return sigPath, nil
+}
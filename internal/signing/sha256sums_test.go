@@ -0,0 +1,64 @@
+package signing
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSHA256SUMS(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"terraform-provider-widget_1.0.0_linux_amd64.zip":   "linux-amd64-contents",
+		"terraform-provider-widget_1.0.0_darwin_arm64.zip":  "darwin-arm64-contents",
+		"terraform-provider-widget_1.0.0_windows_386.zip":   "windows-386-contents",
+		"terraform-provider-widget_1.0.0_SHA256SUMS.ignore": "not a zip, must be skipped",
+		"README.md": "not a zip, must be skipped",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o644); err != nil {
+			t.Fatalf("error writing fixture %q: %v", name, err)
+		}
+	}
+
+	sumsPath, err := GenerateSHA256SUMS(dir, "widget", "1.0.0")
+	if err != nil {
+		t.Fatalf("GenerateSHA256SUMS() error = %v", err)
+	}
+
+	wantPath := filepath.Join(dir, "terraform-provider-widget_1.0.0_SHA256SUMS")
+	if sumsPath != wantPath {
+		t.Errorf("GenerateSHA256SUMS() path = %q, want %q", sumsPath, wantPath)
+	}
+
+	data, err := os.ReadFile(sumsPath)
+	if err != nil {
+		t.Fatalf("error reading generated sums file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	wantNames := []string{
+		"terraform-provider-widget_1.0.0_darwin_arm64.zip",
+		"terraform-provider-widget_1.0.0_linux_amd64.zip",
+		"terraform-provider-widget_1.0.0_windows_386.zip",
+	}
+
+	if len(lines) != len(wantNames) {
+		t.Fatalf("got %d lines, want %d: %q", len(lines), len(wantNames), lines)
+	}
+
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			t.Fatalf("line %d = %q, want exactly 2 fields", i, line)
+		}
+		if fields[1] != wantNames[i] {
+			t.Errorf("line %d filename = %q, want %q (rows must be sorted by filename, not hash)", i, fields[1], wantNames[i])
+		}
+		if len(fields[0]) != 64 {
+			t.Errorf("line %d hash %q is not a 64-character hex SHA-256 digest", i, fields[0])
+		}
+	}
+}
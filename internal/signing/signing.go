@@ -0,0 +1,141 @@
+// Package signing generates the SHA256SUMS manifest and detached GPG signature that Terraform
+// Cloud's registry provider version endpoint requires, so that callers don't have to sign
+// checksums out of band before invoking this action.
+package signing
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+// Config describes where to load the signing key from. Exactly one of KeyFilePath or
+// ArmoredKeyBase64 should be set; KeyFilePath takes precedence when both are present.
+type Config struct {
+	// KeyFilePath is the path to an ASCII-armored GPG private key on disk; binary keys are not
+	// accepted.
+	KeyFilePath string
+	// ArmoredKeyBase64 is a base64-encoded ASCII-armored GPG private key, typically sourced from
+	// a GitHub Actions secret.
+	ArmoredKeyBase64 string
+	// Passphrase decrypts the private key, if it is passphrase-protected.
+	Passphrase string
+}
+
+// Signer produces detached signatures of SHA256SUMS manifests (see GenerateSHA256SUMS) using a
+// single loaded GPG key.
+type Signer struct {
+	entity *openpgp.Entity
+}
+
+// NewSigner loads the private key described by cfg and returns a Signer ready to sign SHA256SUMS
+// manifests.
+func NewSigner(cfg Config) (*Signer, error) {
+	var (
+		keyRdr *os.File
+		err    error
+	)
+
+	switch {
+	case cfg.KeyFilePath != "":
+		if keyRdr, err = os.Open(cfg.KeyFilePath); err != nil {
+			return nil, fmt.Errorf("error opening gpg key file %q: %w", cfg.KeyFilePath, err)
+		}
+		defer keyRdr.Close()
+	case cfg.ArmoredKeyBase64 != "":
+		raw, derr := base64.StdEncoding.DecodeString(strings.TrimSpace(cfg.ArmoredKeyBase64))
+		if derr != nil {
+			return nil, fmt.Errorf("error base64-decoding gpg key: %w", derr)
+		}
+		entity, eerr := entityFromArmored(raw, cfg.Passphrase)
+		if eerr != nil {
+			return nil, eerr
+		}
+		return &Signer{entity: entity}, nil
+	default:
+		return nil, fmt.Errorf("signing: one of KeyFilePath or ArmoredKeyBase64 must be set")
+	}
+
+	entityList, err := openpgp.ReadArmoredKeyRing(keyRdr)
+	if err != nil {
+		return nil, fmt.Errorf("error reading armored gpg key: %w", err)
+	}
+	if len(entityList) == 0 {
+		return nil, fmt.Errorf("signing: gpg key file %q contained no keys", cfg.KeyFilePath)
+	}
+
+	entity := entityList[0]
+	if err = decryptEntity(entity, cfg.Passphrase); err != nil {
+		return nil, err
+	}
+
+	return &Signer{entity: entity}, nil
+}
+
+func entityFromArmored(armored []byte, passphrase string) (*openpgp.Entity, error) {
+	block, err := armor.Decode(strings.NewReader(string(armored)))
+	if err != nil {
+		return nil, fmt.Errorf("error armor-decoding gpg key: %w", err)
+	}
+
+	entity, err := openpgp.ReadEntity(packet.NewReader(block.Body))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing gpg key: %w", err)
+	}
+
+	if err = decryptEntity(entity, passphrase); err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+func decryptEntity(entity *openpgp.Entity, passphrase string) error {
+	if entity.PrivateKey == nil || !entity.PrivateKey.Encrypted {
+		return nil
+	}
+	if passphrase == "" {
+		return fmt.Errorf("signing: gpg key is passphrase-protected but no passphrase was provided")
+	}
+	if err := entity.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+		return fmt.Errorf("error decrypting gpg private key: %w", err)
+	}
+	for _, subKey := range entity.Subkeys {
+		if subKey.PrivateKey != nil && subKey.PrivateKey.Encrypted {
+			if err := subKey.PrivateKey.Decrypt([]byte(passphrase)); err != nil {
+				return fmt.Errorf("error decrypting gpg private subkey: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// KeyID returns the hex-encoded key ID of the loaded signing key, suitable for the
+// CreateProviderVersion "key-id" field.
+func (s *Signer) KeyID() string {
+	return fmt.Sprintf("%X", s.entity.PrimaryKey.KeyId)
+}
+
+// ArmoredPublicKey returns the ASCII-armored public key, suitable for the CreateProviderVersion
+// request body.
+func (s *Signer) ArmoredPublicKey() (string, error) {
+	var buf strings.Builder
+
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", fmt.Errorf("error opening armor encoder: %w", err)
+	}
+	if err = s.entity.Serialize(w); err != nil {
+		return "", fmt.Errorf("error serializing public key: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return "", fmt.Errorf("error closing armor encoder: %w", err)
+	}
+
+	return buf.String(), nil
+}